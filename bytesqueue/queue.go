@@ -0,0 +1,218 @@
+// Package bytesqueue implements a FIFO queue of variable-length byte
+// slices, modeled on the byte queue at the heart of allegro/bigcache.
+//
+// Entries are stored back-to-back in a single []byte ring buffer, each
+// one prefixed with a uvarint length header rather than a fixed-size
+// one, so small entries (a short log line, a small protobuf message)
+// don't pay for header space they don't need. This avoids the one
+// heap allocation per entry that a [][]byte would otherwise cost.
+//
+// Push returns the absolute byte offset an entry was written at, which
+// Get can later use to read that entry back in O(1) without walking
+// the queue from the front. That offset is only valid until the entry
+// is popped, or until a later Push grows the backing array (which
+// compacts and relinearizes the ring, shifting everything).
+package bytesqueue
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultCapacity is the default capacity, in bytes, of a BytesQueue
+// when constructed using New() instead of NewWithCapacity().
+const DefaultCapacity = 1024
+
+// ErrQueueFull is returned by Push when the queue has a maxCapacity
+// and growing the backing array to fit the entry would exceed it.
+var ErrQueueFull = errors.New("Queue full")
+
+// BytesQueue is a FIFO ring buffer of variable-length byte slices.
+type BytesQueue struct {
+	array       []byte
+	maxCapacity int
+	head        int
+	tail        int
+	full        bool // disambiguates head == tail meaning empty vs. full
+}
+
+// New returns a new empty BytesQueue of the default capacity, with no
+// maximum capacity.
+func New() *BytesQueue {
+	return NewWithCapacity(DefaultCapacity, 0)
+}
+
+// NewWithCapacity returns a new empty BytesQueue with the given initial
+// capacity, in bytes. A maxCapacity of 0 means the queue may grow
+// without bound; otherwise Push returns ErrQueueFull once the queue
+// can't grow any further to fit an entry.
+func NewWithCapacity(capacity, maxCapacity int) *BytesQueue {
+	if capacity < 1 {
+		capacity = DefaultCapacity
+	}
+	return &BytesQueue{
+		array:       make([]byte, capacity),
+		maxCapacity: maxCapacity,
+	}
+}
+
+// Len returns the number of bytes (headers and payloads) currently used.
+func (q *BytesQueue) Len() int {
+	if q.full {
+		return len(q.array)
+	}
+	if q.tail >= q.head {
+		return q.tail - q.head
+	}
+	return len(q.array) - q.head + q.tail
+}
+
+// Cap returns the current capacity, in bytes, of the queue.
+func (q *BytesQueue) Cap() int {
+	return len(q.array)
+}
+
+// Push appends p to the back of the queue and returns the absolute
+// byte offset of its header, which can later be passed to Get. The
+// returned index is only valid until the entry is popped, or until a
+// later Push grows the backing array.
+func (q *BytesQueue) Push(p []byte) (int, error) {
+	var header [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(header[:], uint64(len(p)))
+	needed := n + len(p)
+
+	if err := q.ensureCapacity(needed); err != nil {
+		return 0, err
+	}
+
+	index := q.tail
+	pos := q.writeAt(q.tail, header[:n])
+	pos = q.writeAt(pos, p)
+	q.tail = pos
+	if q.tail == q.head {
+		q.full = true
+	}
+	return index, nil
+}
+
+// Get returns a copy of the entry whose header starts at index, as
+// previously returned by Push.
+func (q *BytesQueue) Get(index int) ([]byte, error) {
+	if index < 0 || index >= len(q.array) {
+		return nil, errors.New("Invalid index")
+	}
+	r := &circularReader{array: q.array, pos: index}
+	length, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "bytesqueue: corrupt entry header")
+	}
+	payload, _ := q.readAt(r.pos, int(length))
+	return payload, nil
+}
+
+// Pop removes and returns the entry at the front of the queue.
+func (q *BytesQueue) Pop() ([]byte, error) {
+	if q.Len() == 0 {
+		return nil, errors.New("Queue empty")
+	}
+	r := &circularReader{array: q.array, pos: q.head}
+	length, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "bytesqueue: corrupt entry header")
+	}
+	payload, pos := q.readAt(r.pos, int(length))
+	q.head = pos
+	q.full = false
+	return payload, nil
+}
+
+// ensureCapacity grows the backing array, doubling it, until it has
+// room for needed more bytes, returning ErrQueueFull if doing so would
+// exceed maxCapacity.
+func (q *BytesQueue) ensureCapacity(needed int) error {
+	length := q.Len()
+	if len(q.array)-length >= needed {
+		return nil
+	}
+	newCapacity := len(q.array)
+	for newCapacity-length < needed {
+		newCapacity *= 2
+	}
+	if q.maxCapacity > 0 && newCapacity > q.maxCapacity {
+		return ErrQueueFull
+	}
+	q.resizeTo(newCapacity)
+	return nil
+}
+
+// resizeTo reallocates the backing array at newCapacity, relinearizing
+// the live region to start at index 0.
+func (q *BytesQueue) resizeTo(newCapacity int) {
+	live := q.linearize()
+	newArray := make([]byte, newCapacity)
+	copy(newArray, live)
+	q.array = newArray
+	q.head = 0
+	q.tail = len(live)
+	q.full = false
+}
+
+// linearize returns a copy of the live region, starting at head, in order.
+func (q *BytesQueue) linearize() []byte {
+	length := q.Len()
+	out := make([]byte, length)
+	pos := q.head
+	for i := 0; i < length; i++ {
+		out[i] = q.array[pos]
+		pos++
+		if pos == len(q.array) {
+			pos = 0
+		}
+	}
+	return out
+}
+
+// writeAt writes p starting at the circular position pos, returning
+// the position immediately after the written bytes.
+func (q *BytesQueue) writeAt(pos int, p []byte) int {
+	for _, b := range p {
+		q.array[pos] = b
+		pos++
+		if pos == len(q.array) {
+			pos = 0
+		}
+	}
+	return pos
+}
+
+// readAt reads n bytes starting at the circular position pos, returning
+// a copy of them and the position immediately after.
+func (q *BytesQueue) readAt(pos, n int) ([]byte, int) {
+	out := make([]byte, n)
+	for i := 0; i < n; i++ {
+		out[i] = q.array[pos]
+		pos++
+		if pos == len(q.array) {
+			pos = 0
+		}
+	}
+	return out, pos
+}
+
+// circularReader reads bytes out of a BytesQueue's backing array
+// starting at pos, wrapping at the end, so binary.ReadUvarint can
+// decode a header that straddles the wrap point.
+type circularReader struct {
+	array []byte
+	pos   int
+}
+
+func (r *circularReader) ReadByte() (byte, error) {
+	b := r.array[r.pos]
+	r.pos++
+	if r.pos == len(r.array) {
+		r.pos = 0
+	}
+	return b, nil
+}
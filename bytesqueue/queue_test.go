@@ -0,0 +1,72 @@
+package bytesqueue
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPushGetPop(t *testing.T) {
+	q := New()
+
+	i1, err := q.Push([]byte("hello"))
+	if err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+	i2, err := q.Push([]byte("world"))
+	if err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+
+	got, err := q.Get(i1)
+	if err != nil || !bytes.Equal(got, []byte("hello")) {
+		t.Error("Expected Get(i1) to return hello, got ", got, err)
+	}
+	got, err = q.Get(i2)
+	if err != nil || !bytes.Equal(got, []byte("world")) {
+		t.Error("Expected Get(i2) to return world, got ", got, err)
+	}
+
+	got, err = q.Pop()
+	if err != nil || !bytes.Equal(got, []byte("hello")) {
+		t.Error("Expected Pop to return hello first, got ", got, err)
+	}
+	got, err = q.Pop()
+	if err != nil || !bytes.Equal(got, []byte("world")) {
+		t.Error("Expected Pop to return world second, got ", got, err)
+	}
+
+	if _, err := q.Pop(); err == nil {
+		t.Error("Expected an error popping an empty queue")
+	}
+}
+
+func TestMaxCapacity(t *testing.T) {
+	q := NewWithCapacity(8, 8)
+
+	if _, err := q.Push([]byte("1234")); err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+	_, err := q.Push([]byte("this entry is too large to fit"))
+	if !errors.Is(err, ErrQueueFull) {
+		t.Error("Expected ErrQueueFull once maxCapacity can't be grown past, got ", err)
+	}
+}
+
+func TestGrows(t *testing.T) {
+	q := NewWithCapacity(4, 0)
+	for i := 0; i < 100; i++ {
+		if _, err := q.Push([]byte("some payload bytes")); err != nil {
+			t.Fatal("Unexpected error ", err)
+		}
+	}
+	if q.Cap() <= 4 {
+		t.Error("Expected the queue to have grown past its initial capacity")
+	}
+	for i := 0; i < 100; i++ {
+		got, err := q.Pop()
+		if err != nil || !bytes.Equal(got, []byte("some payload bytes")) {
+			t.Error("Expected FIFO order to be preserved across growth, got ", got, err)
+		}
+	}
+}
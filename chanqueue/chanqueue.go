@@ -0,0 +1,146 @@
+// Package chanqueue provides a channel wrapper that never blocks its
+// sender, buffering overflow in an unbounded (or quota-limited) queue
+// instead of forcing the sender to wait on a slow consumer.
+//
+// A plain Go channel has to pick a fixed buffer size up front: too
+// small and a fast producer blocks on a slow consumer, too large and
+// memory is wasted even when the channel is nearly empty. UnboundedChan
+// instead buffers in a stringqueue.Deque, which grows and shrinks with
+// demand, and forwards to Out() in FIFO order.
+package chanqueue
+
+import (
+	"sync"
+
+	"github.com/Rican7/mmmdatastructures/stringqueue"
+)
+
+// UnboundedChan is a FIFO channel wrapper whose internal buffer grows
+// to hold whatever In() hasn't yet been read from Out().
+type UnboundedChan[T any] struct {
+	in  chan T
+	out chan T
+
+	mu    sync.Mutex
+	buf   *stringqueue.Deque[T]
+	quota int
+}
+
+// New returns a new UnboundedChan with no quota: its internal buffer
+// may grow without bound while Out() goes unread.
+func New[T any]() *UnboundedChan[T] {
+	return NewWithQuota[T](0)
+}
+
+// NewWithQuota returns a new UnboundedChan whose internal buffer will
+// never hold more than quota entries; once the quota is reached, In()
+// blocks until Out() has drained enough of the backlog to make room.
+// A quota of 0 means the buffer may grow without bound.
+func NewWithQuota[T any](quota int) *UnboundedChan[T] {
+	c := &UnboundedChan[T]{
+		in:    make(chan T),
+		out:   make(chan T),
+		buf:   stringqueue.NewDeque[T](),
+		quota: quota,
+	}
+	go c.run()
+	return c
+}
+
+// In returns the channel to send values into. Sends never block on a
+// slow consumer; they only block when a quota has been reached.
+func (c *UnboundedChan[T]) In() chan<- T {
+	return c.in
+}
+
+// Out returns the channel to receive values from, in FIFO order. It
+// is closed once In() is closed and the internal buffer is drained.
+func (c *UnboundedChan[T]) Out() <-chan T {
+	return c.out
+}
+
+// Len returns the number of values currently buffered.
+func (c *UnboundedChan[T]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.Len()
+}
+
+// Cap returns the current capacity of the internal buffer.
+func (c *UnboundedChan[T]) Cap() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.Cap()
+}
+
+// run is the single goroutine that owns moving values from in, through
+// the internal buffer, to out.
+func (c *UnboundedChan[T]) run() {
+	defer close(c.out)
+
+	for {
+		c.mu.Lock()
+		empty := c.buf.Len() == 0
+		c.mu.Unlock()
+
+		if empty {
+			v, ok := <-c.in
+			if !ok {
+				return
+			}
+			c.push(v)
+		}
+
+		c.mu.Lock()
+		front, _ := c.buf.Front()
+		c.mu.Unlock()
+
+		select {
+		case v, ok := <-c.in:
+			if !ok {
+				c.drain()
+				return
+			}
+			c.push(v)
+		case c.out <- front:
+			c.mu.Lock()
+			c.buf.PopFront()
+			c.mu.Unlock()
+		}
+	}
+}
+
+// push buffers v, applying backpressure by feeding the front of the
+// buffer to out until there's room, if a quota is set.
+func (c *UnboundedChan[T]) push(v T) {
+	for {
+		c.mu.Lock()
+		if c.quota <= 0 || c.buf.Len() < c.quota {
+			c.buf.PushBack(v)
+			c.mu.Unlock()
+			return
+		}
+		front, _ := c.buf.Front()
+		c.mu.Unlock()
+
+		c.out <- front
+
+		c.mu.Lock()
+		c.buf.PopFront()
+		c.mu.Unlock()
+	}
+}
+
+// drain empties the buffer into out once In() has been closed.
+func (c *UnboundedChan[T]) drain() {
+	for {
+		c.mu.Lock()
+		if c.buf.Len() == 0 {
+			c.mu.Unlock()
+			return
+		}
+		v, _ := c.buf.PopFront()
+		c.mu.Unlock()
+		c.out <- v
+	}
+}
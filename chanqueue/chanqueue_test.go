@@ -0,0 +1,49 @@
+package chanqueue
+
+import "testing"
+
+func TestFIFOOrder(t *testing.T) {
+	c := New[int]()
+	for i := 0; i < 5; i++ {
+		c.In() <- i
+	}
+	close(c.In())
+
+	for i := 0; i < 5; i++ {
+		got, ok := <-c.Out()
+		if !ok {
+			t.Fatal("Expected Out() to still be open at element ", i)
+		}
+		if got != i {
+			t.Error("Expected ", i, ", got ", got)
+		}
+	}
+
+	if _, ok := <-c.Out(); ok {
+		t.Error("Expected Out() to be closed after the buffer drains")
+	}
+}
+
+func TestQuotaAppliesBackpressure(t *testing.T) {
+	c := NewWithQuota[int](2)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			c.In() <- i
+		}
+		close(c.In())
+		close(done)
+	}()
+
+	for i := 0; i < 5; i++ {
+		got := <-c.Out()
+		if got != i {
+			t.Error("Expected ", i, ", got ", got)
+		}
+		if n := c.Len(); n > 2 {
+			t.Error("Expected the buffer to never hold more than the quota of 2, got ", n)
+		}
+	}
+	<-done
+}
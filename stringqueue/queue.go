@@ -1,108 +1,335 @@
-// Package stringqueue implements a queue for strings.
+// Package stringqueue implements a generic double-ended queue (deque).
 //
-// The internal representation is a slice of strings
-// that gets used as a circular buffer.
-// This is instead of a more traditional approach
-// that would use a linked list of nodes.
-// The assumption is that contiguous slabs of RAM
-// will generally provide more performance over pointers
-// to nodes potentially scattered about the heap.
+// The internal representation is a slice of T that gets used as a
+// circular buffer. This is instead of a more traditional approach
+// that would use a linked list of nodes. The assumption is that
+// contiguous slabs of RAM will generally provide more performance
+// over pointers to nodes potentially scattered about the heap.
 //
-// There is a downside: whereas enqueueing to a
-// linked list is always O(1), enqueueing here will
-// be O(1) except for when the internal slice of strings
-// has to be resized; then, enqueueing will be O(n)
-// where n is the size of the queue before being resized.
+// There is a downside: whereas pushing to a linked list is always
+// O(1), pushing here will be O(1) except for when the internal
+// buffer has to grow; then, pushing will be O(n) where n is the
+// size of the queue before being resized.
 //
-// Therefore, when asking for a new instance of the
-// queue, pick a capacity that you think won't need to grow.
+// The backing buffer always has a capacity that is a power of two.
+// This lets At translate an external, tail-relative index into an
+// internal buffer position with a bitmask instead of a modulo, and
+// lets the capacity double by simply shifting a bit.
 //
-// When the queue does need to grow, it always uses a capacity
-// that is twice the current capacity. This is not tunable.
+// The package is named stringqueue for historical reasons: it
+// originally only held strings. IntQueue is kept as an alias of
+// Deque[string] so existing callers of New() and NewWithCapacity()
+// keep working unchanged; use NewDeque and NewDequeWithCapacity to
+// get a deque of some other element type.
 package stringqueue
 
-import "github.com/pkg/errors"
+import (
+	"iter"
 
-// IntQueue holds the data and state of the queue.
-type IntQueue struct {
-	data     []string
-	head     int
-	tail     int
-	capacity int
-	length   int
-}
+	"github.com/pkg/errors"
+)
 
-// DefauiltCapacity is the default capacity of the IntQueue
-// when constructed using New() instead of NewWithCapacity().
+// DefaultCapacity is the default capacity of a Deque when constructed
+// using New() or NewDeque() instead of one of the *WithCapacity variants.
 const DefaultCapacity = 32
 
+// ErrFull is returned by PushFront/PushBack when the queue has a
+// maxCapacity (see NewBounded/NewDequeBounded) and is already full.
+var ErrFull = errors.New("Queue full")
+
+// Deque holds the data and state of a generic double-ended queue.
+type Deque[T any] struct {
+	buf         []T
+	tail        int // index of the front element
+	head        int // index of the next free slot (one past the back element)
+	length      int
+	minCapacity int
+	maxCapacity int
+}
+
+// IntQueue is kept as an alias for back-compat: it predates the
+// generic rewrite, back when this package only dealt with strings.
+type IntQueue = Deque[string]
+
 // New returns a new empty queue for strings of the default capacity.
-func New() (q *IntQueue) {
+func New() *IntQueue {
 	return NewWithCapacity(DefaultCapacity)
 }
 
 // NewWithCapacity returns a new empty queue for strings with the requested capacity.
-func NewWithCapacity(capacity int) (q *IntQueue) {
-	q = new(IntQueue)
-	q.data = make([]string, capacity, capacity)
-	q.head = -1
-	q.tail = -1
-	q.capacity = capacity
-	q.length = 0
+func NewWithCapacity(capacity int) *IntQueue {
+	return NewDequeWithCapacity[string](capacity)
+}
+
+// NewBounded returns a new empty queue for strings that will never grow
+// past max elements; PushBack/PushFront return ErrFull instead once full.
+func NewBounded(initial, max int) *IntQueue {
+	return NewDequeBounded[string](initial, max)
+}
+
+// NewDeque returns a new empty deque of the default capacity.
+func NewDeque[T any]() *Deque[T] {
+	return NewDequeWithCapacity[T](DefaultCapacity)
+}
+
+// NewDequeWithCapacity returns a new empty deque with at least the requested capacity.
+func NewDequeWithCapacity[T any](capacity int) *Deque[T] {
+	if capacity < 1 {
+		capacity = DefaultCapacity
+	}
+	capacity = nextPowerOfTwo(capacity)
+	return &Deque[T]{
+		buf:         make([]T, capacity),
+		minCapacity: DefaultCapacity,
+	}
+}
+
+// NewDequeBounded returns a new empty deque that will never grow past
+// max elements; PushBack/PushFront return ErrFull instead once full.
+func NewDequeBounded[T any](initial, max int) *Deque[T] {
+	q := NewDequeWithCapacity[T](initial)
+	q.maxCapacity = max
 	return q
 }
 
-// Enqueue enqueues a string. Returns an error if the size
-// of the queue cannot be grown any more to accommodate
-// the added string.
-func (q *IntQueue) Enqueue(i string) error {
-	if q.length+1 > q.capacity {
-		new_capacity := q.capacity * 2
-		// if new_cap became negative, we have exceeded
-		// our capacity by doing one bit-shift too far
-		if new_capacity < 0 {
-			return errors.New("Capacity exceeded")
+// Len returns the number of elements currently in the queue.
+func (q *Deque[T]) Len() int {
+	return q.length
+}
+
+// Cap returns the current capacity of the queue's backing buffer.
+func (q *Deque[T]) Cap() int {
+	return len(q.buf)
+}
+
+// SetMinCapacity pins a floor below which the queue will never
+// automatically shrink its backing buffer, to avoid thrashing when
+// the queue's size oscillates around a shrink threshold. n is
+// rounded up to the nearest power of two.
+func (q *Deque[T]) SetMinCapacity(n int) {
+	if n < 1 {
+		n = 1
+	}
+	q.minCapacity = nextPowerOfTwo(n)
+}
+
+// Front returns the element at the front of the queue without removing it.
+func (q *Deque[T]) Front() (T, error) {
+	var zero T
+	if q.length == 0 {
+		return zero, errors.New("Queue empty")
+	}
+	return q.buf[q.tail], nil
+}
+
+// Back returns the element at the back of the queue without removing it.
+func (q *Deque[T]) Back() (T, error) {
+	var zero T
+	if q.length == 0 {
+		return zero, errors.New("Queue empty")
+	}
+	return q.buf[(q.head-1)&(len(q.buf)-1)], nil
+}
+
+// At returns the element at index i, where 0 is the front of the queue.
+func (q *Deque[T]) At(i int) (T, error) {
+	var zero T
+	if i < 0 || i >= q.length {
+		return zero, errors.New("Index out of range")
+	}
+	return q.buf[(q.tail+i)&(len(q.buf)-1)], nil
+}
+
+// Set replaces the element at index i, where 0 is the front of the queue.
+func (q *Deque[T]) Set(i int, v T) error {
+	if i < 0 || i >= q.length {
+		return errors.New("Index out of range")
+	}
+	q.buf[(q.tail+i)&(len(q.buf)-1)] = v
+	return nil
+}
+
+// Range walks the queue from front to back without mutating it,
+// calling f with each element's index and value. It stops early if f
+// returns false.
+func (q *Deque[T]) Range(f func(i int, v T) bool) {
+	for i := 0; i < q.length; i++ {
+		if !f(i, q.buf[(q.tail+i)&(len(q.buf)-1)]) {
+			return
+		}
+	}
+}
+
+// Snapshot returns a freshly allocated slice holding a copy of the
+// queue's elements, in front-to-back order.
+func (q *Deque[T]) Snapshot() []T {
+	out := make([]T, q.length)
+	for i := range out {
+		out[i] = q.buf[(q.tail+i)&(len(q.buf)-1)]
+	}
+	return out
+}
+
+// All returns an iterator over the queue's elements, in front-to-back
+// order, so callers can range over it directly: for i, v := range q.All().
+func (q *Deque[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		q.Range(yield)
+	}
+}
+
+// PushBack adds v to the back of the queue. Returns ErrFull if the
+// queue has a maxCapacity and is already full, or an error if the
+// queue would need to grow past what it can address.
+func (q *Deque[T]) PushBack(v T) error {
+	if q.maxCapacity > 0 && q.length >= q.maxCapacity {
+		return ErrFull
+	}
+	if q.length == len(q.buf) {
+		if err := q.grow(); err != nil {
+			return err
 		}
-		q.resize(new_capacity)
 	}
+	q.buf[q.head] = v
+	q.head = (q.head + 1) & (len(q.buf) - 1)
 	q.length++
-	q.head++
-	if q.head == q.capacity {
-		q.head = 0
+	return nil
+}
+
+// PushFront adds v to the front of the queue. Returns ErrFull if the
+// queue has a maxCapacity and is already full, or an error if the
+// queue would need to grow past what it can address.
+func (q *Deque[T]) PushFront(v T) error {
+	if q.maxCapacity > 0 && q.length >= q.maxCapacity {
+		return ErrFull
 	}
-	q.data[q.head] = i
+	if q.length == len(q.buf) {
+		if err := q.grow(); err != nil {
+			return err
+		}
+	}
+	q.tail = (q.tail - 1) & (len(q.buf) - 1)
+	q.buf[q.tail] = v
+	q.length++
 	return nil
 }
 
-// Head can be earlier in array than tail, so
-// we can't just copy; we could overwrite the tail.
-// Instead, we may as well copy the queue in order
-// into the new array. The Dequeue() method gives us
-// every element in the correct order already, so we
-// just leverage that.
-func (q *IntQueue) resize(new_capacity int) {
-	new_data := make([]string, new_capacity, new_capacity)
-	var err error
-	var i string
-	for err = nil; err == nil; i, err = q.Dequeue() {
-		new_data = append(new_data, i)
-	}
-	q.head = q.length - 1
-	q.tail = 0
-	q.capacity = new_capacity
-	q.data = new_data
+// PopFront removes and returns the element at the front of the queue.
+func (q *Deque[T]) PopFront() (T, error) {
+	var zero T
+	if q.length == 0 {
+		return zero, errors.New("Queue empty")
+	}
+	v := q.buf[q.tail]
+	q.buf[q.tail] = zero
+	q.tail = (q.tail + 1) & (len(q.buf) - 1)
+	q.length--
+	q.maybeShrink()
+	return v, nil
 }
 
-// Dequeue dequeues a string. It returns the dequeued string
-// or an error of the queue is empty.
-func (q *IntQueue) Dequeue() (string, error) {
-	if q.length-1 < 0 {
-		return "", errors.New("Queue empty")
+// PopBack removes and returns the element at the back of the queue.
+func (q *Deque[T]) PopBack() (T, error) {
+	var zero T
+	if q.length == 0 {
+		return zero, errors.New("Queue empty")
 	}
+	q.head = (q.head - 1) & (len(q.buf) - 1)
+	v := q.buf[q.head]
+	q.buf[q.head] = zero
 	q.length--
-	q.tail++
-	if q.tail == q.capacity {
-		q.tail = 0
+	q.maybeShrink()
+	return v, nil
+}
+
+// Enqueue enqueues v at the back of the queue. Kept as an
+// alias of PushBack for back-compat.
+func (q *Deque[T]) Enqueue(v T) error {
+	return q.PushBack(v)
+}
+
+// Dequeue dequeues an element from the front of the queue. Kept as an
+// alias of PopFront for back-compat.
+func (q *Deque[T]) Dequeue() (T, error) {
+	return q.PopFront()
+}
+
+// Clear empties the queue, releasing references held by its elements.
+func (q *Deque[T]) Clear() {
+	var zero T
+	for i := 0; i < q.length; i++ {
+		q.buf[(q.tail+i)&(len(q.buf)-1)] = zero
+	}
+	q.tail = 0
+	q.head = 0
+	q.length = 0
+}
+
+// grow doubles the capacity of the backing buffer, copying the live
+// region into the new buffer in FIFO order with at most two copy()
+// calls, so growing is always O(n) in the size of the queue rather
+// than O(n^2).
+func (q *Deque[T]) grow() error {
+	newCapacity := len(q.buf) * 2
+	if newCapacity <= 0 {
+		return errors.New("Capacity exceeded")
+	}
+	q.resizeTo(newCapacity)
+	return nil
+}
+
+// maybeShrink halves the backing buffer once a Pop has left it
+// less than a quarter full, to release memory held by a queue that
+// previously spiked in size. It never shrinks below DefaultCapacity
+// or the floor pinned by SetMinCapacity.
+func (q *Deque[T]) maybeShrink() {
+	capacity := len(q.buf)
+	if capacity <= DefaultCapacity || capacity <= q.minCapacity {
+		return
+	}
+	if q.length >= capacity/4 {
+		return
+	}
+	newCapacity := capacity / 2
+	if newCapacity < DefaultCapacity {
+		newCapacity = DefaultCapacity
+	}
+	if newCapacity < q.minCapacity {
+		newCapacity = q.minCapacity
 	}
-	return q.data[q.tail], nil
-}
\ No newline at end of file
+	if newCapacity == capacity {
+		return
+	}
+	q.resizeTo(newCapacity)
+}
+
+// resizeTo reallocates the backing buffer at newCapacity.
+func (q *Deque[T]) resizeTo(newCapacity int) {
+	newBuf := make([]T, newCapacity)
+	if q.length > 0 {
+		n := copy(newBuf, q.buf[q.tail:])
+		if n < q.length {
+			copy(newBuf[n:], q.buf[:q.length-n])
+		}
+	}
+	q.buf = newBuf
+	q.tail = 0
+	q.head = q.length
+}
+
+// nextPowerOfTwo rounds n up to the nearest power of two, with a
+// minimum result of 1.
+func nextPowerOfTwo(n int) int {
+	if n < 1 {
+		return 1
+	}
+	n--
+	n |= n >> 1
+	n |= n >> 2
+	n |= n >> 4
+	n |= n >> 8
+	n |= n >> 16
+	n |= n >> 32
+	n++
+	return n
+}
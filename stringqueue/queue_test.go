@@ -1,61 +1,256 @@
 package stringqueue
 
-import "testing"
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
 
 func TestCreate(t *testing.T) {
 	q := New()
-	if q.head != -1 {
-		t.Error("Expected Head to be -1, got ", q.head)
+	if q.Len() != 0 {
+		t.Error("Expected Len to be 0, got ", q.Len())
 	}
-	if q.tail != -1 {
-		t.Error("Expected Tail to be -1, got ", q.tail)
+	if q.Cap() != DefaultCapacity {
+		t.Error("Expected Cap to be ", DefaultCapacity, ", got ", q.Cap())
 	}
 }
 
 func TestEnqueue(t *testing.T) {
 	q := New()
 	q.Enqueue("5")
-	if q.tail != -1 {
-		t.Error("Expected Tail to be -1, got ", q.tail)
+	if q.Len() != 1 {
+		t.Error("Expected Len to be 1, got ", q.Len())
 	}
-	if q.head != 0 {
-		t.Error("Expected Tail to be 0, got ", q.tail)
+	front, _ := q.Front()
+	if front != "5" {
+		t.Error("Expected Front to be 5, got ", front)
+	}
+	back, _ := q.Back()
+	if back != "5" {
+		t.Error("Expected Back to be 5, got ", back)
 	}
 }
 
 func TestFill(t *testing.T) {
 	q := New()
 	for i := 1; i <= 32; i++ {
-		q.Enqueue(string(i))
+		q.Enqueue(string(rune(i)))
 	}
 	q.Enqueue("33")
-	if q.capacity != 64 {
-		t.Error("Expected capacity to double")
+	if q.Cap() != 64 {
+		t.Error("Expected capacity to double, got ", q.Cap())
 	}
 }
 
 func TestDrain(t *testing.T) {
 	q := New()
 	for i := 1; i <= 32; i++ {
-		q.Enqueue(string(i))
+		q.Enqueue(string(rune(i)))
 	}
 	var i string
 	var err error
-	for j := 0; j < 32; j++ {
+	for j := 1; j <= 32; j++ {
 		i, err = q.Dequeue()
-		if i != string(j+1) {
+		if i != string(rune(j)) {
 			t.Error("Expected i to be ", j, ", got ", i)
 		}
+		if err != nil {
+			t.Error("Unexpected error ", err)
+		}
 	}
-	i, err = q.Dequeue()
+	_, err = q.Dequeue()
 	if err == nil {
 		t.Error("Expected err to be present")
 	}
 	for j := 1; j < 35; j++ {
-		q.Enqueue(string(j))
+		q.Enqueue(string(rune(j)))
 		i, err = q.Dequeue()
-		if i != string(j) {
+		if i != string(rune(j)) {
 			t.Error("Expected i to be ", j, ", got ", i)
 		}
 	}
 }
+
+func TestPushFrontPopBack(t *testing.T) {
+	q := New()
+	q.PushBack("b")
+	q.PushFront("a")
+	q.PushBack("c")
+
+	front, _ := q.Front()
+	if front != "a" {
+		t.Error("Expected Front to be a, got ", front)
+	}
+
+	back, _ := q.PopBack()
+	if back != "c" {
+		t.Error("Expected PopBack to return c, got ", back)
+	}
+
+	v, _ := q.At(0)
+	if v != "a" {
+		t.Error("Expected At(0) to be a, got ", v)
+	}
+	v, _ = q.At(1)
+	if v != "b" {
+		t.Error("Expected At(1) to be b, got ", v)
+	}
+
+	if err := q.Set(1, "bb"); err != nil {
+		t.Error("Unexpected error ", err)
+	}
+	v, _ = q.At(1)
+	if v != "bb" {
+		t.Error("Expected At(1) to be bb after Set, got ", v)
+	}
+}
+
+// TestGrowAcrossWrappedHeadAndTail regresses a historical bug where
+// resize (now resizeTo/grow) appended the live elements onto a buffer
+// already allocated at full length, leaving head and tail pointing at
+// the wrong slots. It grows a queue whose live region wraps around the
+// end of the backing buffer and checks that FIFO order survives.
+func TestGrowAcrossWrappedHeadAndTail(t *testing.T) {
+	q := NewWithCapacity(4)
+
+	// Push and pop enough that tail sits on the last slot (index 3),
+	// so the fill below wraps around before the queue needs to grow.
+	for i := 0; i < 3; i++ {
+		q.Enqueue("warmup")
+		q.Dequeue()
+	}
+
+	// Filling to capacity leaves the live region wrapped (it starts at
+	// index 3 and continues at 0..2); one more Enqueue forces a grow
+	// while wrapped.
+	for i := 1; i <= 4; i++ {
+		q.Enqueue(string(rune('0' + i)))
+	}
+	q.Enqueue("5")
+	if q.Cap() != 8 {
+		t.Fatal("Expected capacity to double to 8, got ", q.Cap())
+	}
+
+	for i := 1; i <= 5; i++ {
+		v, err := q.Dequeue()
+		if err != nil {
+			t.Fatal("Unexpected error ", err)
+		}
+		want := string(rune('0' + i))
+		if v != want {
+			t.Errorf("Expected %q at position %d after a wrapped grow, got %q", want, i, v)
+		}
+	}
+}
+
+func TestBounded(t *testing.T) {
+	q := NewBounded(4, 4)
+	for i := 0; i < 4; i++ {
+		if err := q.Enqueue("x"); err != nil {
+			t.Fatal("Unexpected error filling a bounded queue to its max ", err)
+		}
+	}
+	err := q.Enqueue("overflow")
+	if !errors.Is(err, ErrFull) {
+		t.Error("Expected ErrFull once a bounded queue reaches its max, got ", err)
+	}
+	if q.Cap() != 4 {
+		t.Error("Expected a bounded queue to never grow past max, got cap ", q.Cap())
+	}
+
+	q.Dequeue()
+	if err := q.Enqueue("y"); err != nil {
+		t.Error("Expected room to enqueue after a Dequeue, got ", err)
+	}
+}
+
+func TestShrinkOnDrain(t *testing.T) {
+	q := New()
+	for i := 0; i < 200; i++ {
+		q.Enqueue("x")
+	}
+	grown := q.Cap()
+	if grown <= DefaultCapacity {
+		t.Fatal("Expected capacity to have grown past DefaultCapacity, got ", grown)
+	}
+
+	for q.Len() > grown/4 {
+		q.Dequeue()
+	}
+	// One more Dequeue should cross the shrink threshold.
+	q.Dequeue()
+
+	if q.Cap() >= grown {
+		t.Error("Expected capacity to shrink after draining below 1/4 full, got ", q.Cap())
+	}
+	if q.Cap() < DefaultCapacity {
+		t.Error("Expected capacity to never shrink below DefaultCapacity, got ", q.Cap())
+	}
+}
+
+func TestSetMinCapacity(t *testing.T) {
+	q := New()
+	q.SetMinCapacity(128)
+	for i := 0; i < 200; i++ {
+		q.Enqueue("x")
+	}
+	for q.Len() > 0 {
+		q.Dequeue()
+	}
+	if q.Cap() < 128 {
+		t.Error("Expected capacity to never shrink below the pinned floor of 128, got ", q.Cap())
+	}
+}
+
+func TestRangeSnapshotAndAll(t *testing.T) {
+	q := New()
+	q.Enqueue("a")
+	q.Enqueue("b")
+	q.Enqueue("c")
+
+	var ranged []string
+	q.Range(func(i int, v string) bool {
+		ranged = append(ranged, v)
+		return true
+	})
+	want := []string{"a", "b", "c"}
+	if !reflect.DeepEqual(ranged, want) {
+		t.Error("Expected Range to walk front-to-back, got ", ranged)
+	}
+
+	snap := q.Snapshot()
+	if !reflect.DeepEqual(snap, want) {
+		t.Error("Expected Snapshot to return front-to-back order, got ", snap)
+	}
+	// Mutating the snapshot must not affect the queue.
+	snap[0] = "mutated"
+	if front, _ := q.Front(); front != "a" {
+		t.Error("Expected Snapshot to be a copy, queue Front changed to ", front)
+	}
+
+	var all []string
+	for _, v := range q.All() {
+		all = append(all, v)
+	}
+	if !reflect.DeepEqual(all, want) {
+		t.Error("Expected All to walk front-to-back, got ", all)
+	}
+
+	if q.Len() != 3 {
+		t.Error("Expected Range/Snapshot/All to not mutate the queue, got Len ", q.Len())
+	}
+}
+
+func TestClear(t *testing.T) {
+	q := New()
+	q.Enqueue("a")
+	q.Enqueue("b")
+	q.Clear()
+	if q.Len() != 0 {
+		t.Error("Expected Len to be 0 after Clear, got ", q.Len())
+	}
+	if _, err := q.Front(); err == nil {
+		t.Error("Expected Front to error on an empty queue")
+	}
+}